@@ -0,0 +1,301 @@
+// Package cache manages a local, on-disk cache of cloned or downloaded
+// cookiecutter templates under ~/.gophycutter (overridable via the
+// GOPHYCUTTER_HOME environment variable), so repeat runs against the same
+// template don't require a fresh network fetch and can pin a specific
+// ref.
+package cache
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// homeEnvVar overrides the default ~/.gophycutter cache location.
+	homeEnvVar = "GOPHYCUTTER_HOME"
+
+	templatesDirName = "templates"
+)
+
+// Home returns the cache root directory, creating it if necessary.
+func Home() (string, error) {
+	if dir := os.Getenv(homeEnvVar); dir != "" {
+		return dir, ensureDir(dir)
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("error resolving home directory: %v", err)
+	}
+
+	dir := filepath.Join(homeDir, ".gophycutter")
+	return dir, ensureDir(dir)
+}
+
+func ensureDir(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("error creating cache directory %s: %v", dir, err)
+	}
+	return nil
+}
+
+// Key derives a stable, filesystem-safe cache key from a template URL, so
+// the same URL always resolves to the same on-disk location.
+func Key(rawURL string) string {
+	sum := sha256.Sum256([]byte(rawURL))
+	hash := hex.EncodeToString(sum[:])[:12]
+
+	base := strings.TrimSuffix(path.Base(strings.TrimRight(rawURL, "/")), path.Ext(rawURL))
+	base = strings.TrimSuffix(base, ".tar")
+	if base == "" || base == "." || base == "/" {
+		return hash
+	}
+	return base + "-" + hash
+}
+
+// templatePath returns the cache directory a template URL is stored
+// under, without checking whether it exists yet.
+func templatePath(rawURL string) (string, error) {
+	home, err := Home()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, templatesDirName, Key(rawURL)), nil
+}
+
+// Lookup returns the cached local path for rawURL if it has already been
+// fetched, performing no network access. It's used for offline replay.
+func Lookup(rawURL string) (string, bool, error) {
+	dir, err := templatePath(rawURL)
+	if err != nil {
+		return "", false, err
+	}
+	if _, err := os.Stat(dir); err != nil {
+		return "", false, nil
+	}
+	return dir, true, nil
+}
+
+// Resolve makes rawURL available locally under the cache, fetching or
+// updating it as needed, and returns the local directory to generate
+// from. Git repository URLs are cloned on first use and `git pull`ed
+// (then checked out to ref, if given) on subsequent runs. URLs ending in
+// .zip or .tar.gz/.tgz are downloaded and extracted instead.
+func Resolve(rawURL, ref string) (string, error) {
+	dir, err := templatePath(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	switch archiveKind(rawURL) {
+	case archiveZip, archiveTarGz:
+		if _, err := os.Stat(dir); err == nil {
+			return dir, nil
+		}
+		return dir, fetchArchive(rawURL, dir)
+	default:
+		return dir, syncGit(rawURL, dir, ref)
+	}
+}
+
+type archiveFormat int
+
+const (
+	archiveNone archiveFormat = iota
+	archiveZip
+	archiveTarGz
+)
+
+func archiveKind(rawURL string) archiveFormat {
+	lower := strings.ToLower(strings.TrimRight(rawURL, "/"))
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return archiveZip
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return archiveTarGz
+	default:
+		return archiveNone
+	}
+}
+
+// syncGit clones rawURL into dir if it isn't already cached, otherwise
+// pulls the latest changes, then checks out ref if one is given.
+func syncGit(rawURL, dir, ref string) error {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		cmd := exec.Command("git", "clone", rawURL, dir)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("error cloning %s: %v", rawURL, err)
+		}
+	} else {
+		cmd := exec.Command("git", "pull")
+		cmd.Dir = dir
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("error updating cached template %s: %v", dir, err)
+		}
+	}
+
+	if ref == "" {
+		return nil
+	}
+
+	cmd := exec.Command("git", "checkout", ref)
+	cmd.Dir = dir
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("error checking out %s: %v", ref, err)
+	}
+	return nil
+}
+
+// fetchArchive downloads a .zip or .tar.gz template archive over HTTP and
+// extracts it into dir.
+func fetchArchive(rawURL, dir string) error {
+	resp, err := http.Get(rawURL)
+	if err != nil {
+		return fmt.Errorf("error downloading %s: %v", rawURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("error downloading %s: unexpected status %s", rawURL, resp.Status)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("error creating template directory %s: %v", dir, err)
+	}
+
+	switch archiveKind(rawURL) {
+	case archiveZip:
+		return extractZip(resp.Body, dir)
+	case archiveTarGz:
+		return extractTarGz(resp.Body, dir)
+	default:
+		return fmt.Errorf("unsupported archive format for %s", rawURL)
+	}
+}
+
+func extractZip(body io.Reader, dir string) error {
+	tmp, err := os.CreateTemp("", "gophycutter-archive-*.zip")
+	if err != nil {
+		return fmt.Errorf("error buffering archive: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if _, err := io.Copy(tmp, body); err != nil {
+		return fmt.Errorf("error buffering archive: %v", err)
+	}
+
+	reader, err := zip.OpenReader(tmp.Name())
+	if err != nil {
+		return fmt.Errorf("error opening zip archive: %v", err)
+	}
+	defer reader.Close()
+
+	for _, file := range reader.File {
+		target, err := safeJoin(dir, file.Name)
+		if err != nil {
+			return err
+		}
+		if file.FileInfo().IsDir() {
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+
+		src, err := file.Open()
+		if err != nil {
+			return err
+		}
+		if err := writeFile(target, src, file.Mode()); err != nil {
+			src.Close()
+			return err
+		}
+		src.Close()
+	}
+	return nil
+}
+
+func extractTarGz(body io.Reader, dir string) error {
+	gzReader, err := gzip.NewReader(body)
+	if err != nil {
+		return fmt.Errorf("error opening tar.gz archive: %v", err)
+	}
+	defer gzReader.Close()
+
+	tarReader := tar.NewReader(gzReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error reading tar archive: %v", err)
+		}
+
+		target, err := safeJoin(dir, header.Name)
+		if err != nil {
+			return err
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			if err := writeFile(target, tarReader, os.FileMode(header.Mode)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// safeJoin joins an archive entry name onto dir, rejecting entries (via
+// "../" segments or an absolute path) that would extract outside dir, a.k.a.
+// Zip Slip.
+func safeJoin(dir, name string) (string, error) {
+	target := filepath.Join(dir, name)
+
+	if rel, err := filepath.Rel(dir, target); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(os.PathSeparator)) {
+		return "", fmt.Errorf("archive entry %q escapes destination directory", name)
+	}
+
+	return target, nil
+}
+
+func writeFile(target string, src io.Reader, mode os.FileMode) error {
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
+	if err != nil {
+		return fmt.Errorf("error creating %s: %v", target, err)
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, src); err != nil {
+		return fmt.Errorf("error writing %s: %v", target, err)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package cache
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSafeJoin(t *testing.T) {
+	dir := "/cache/templates/demo"
+
+	cases := []struct {
+		name    string
+		entry   string
+		wantErr bool
+	}{
+		{"plain file", "main.go", false},
+		{"nested file", "src/main.go", false},
+		{"dot-prefixed but contained", "./src/main.go", false},
+		{"parent traversal", "../../../tmp/pwned.txt", true},
+		{"leading slash is joined, not absolute", "/etc/passwd", false},
+		{"traversal that returns to dir", "sub/../../demo/ok.txt", false},
+		{"traversal past dir even if named back", "sub/../../../etc/passwd", true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			target, err := safeJoin(dir, tc.entry)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("safeJoin(%q, %q) = %q, want error", dir, tc.entry, target)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("safeJoin(%q, %q) returned unexpected error: %v", dir, tc.entry, err)
+			}
+		})
+	}
+}
+
+// buildZip constructs an in-memory zip archive with one entry per name.
+func buildZip(t *testing.T, names []string) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, name := range names {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatalf("error creating zip entry %q: %v", name, err)
+		}
+		if _, err := f.Write([]byte("payload")); err != nil {
+			t.Fatalf("error writing zip entry %q: %v", name, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("error closing zip writer: %v", err)
+	}
+	return &buf
+}
+
+func TestExtractZipRejectsTraversal(t *testing.T) {
+	dir := t.TempDir()
+	buf := buildZip(t, []string{"../../outside.txt"})
+
+	if err := extractZip(buf, dir); err == nil {
+		t.Fatal("extractZip accepted a path-traversing entry, want error")
+	}
+
+	if _, err := os.Stat(filepath.Join(filepath.Dir(filepath.Dir(dir)), "outside.txt")); err == nil {
+		t.Fatal("extractZip wrote a file outside the destination directory")
+	}
+}
+
+func TestExtractZipAllowsContainedEntries(t *testing.T) {
+	dir := t.TempDir()
+	buf := buildZip(t, []string{"project/main.go"})
+
+	if err := extractZip(buf, dir); err != nil {
+		t.Fatalf("extractZip rejected a contained entry: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "project", "main.go")); err != nil {
+		t.Fatalf("extractZip did not write the expected file: %v", err)
+	}
+}
+
+func TestKeyIsStableAndFilesystemSafe(t *testing.T) {
+	a := Key("https://example.com/templates/demo.git")
+	b := Key("https://example.com/templates/demo.git")
+	if a != b {
+		t.Fatalf("Key is not stable: %q != %q", a, b)
+	}
+	if filepath.Base(a) != a {
+		t.Fatalf("Key %q is not a safe single path segment", a)
+	}
+}
@@ -0,0 +1,66 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const replayDirName = "replay"
+
+// AnswersPath returns the path the answered context for a template is
+// recorded to, for later offline replay.
+func AnswersPath(rawURL string) (string, error) {
+	home, err := Home()
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(home, replayDirName)
+	if err := ensureDir(dir); err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, Key(rawURL)+".json"), nil
+}
+
+// SaveAnswers records the answered cookiecutter variables for a template
+// so a later `replay` can regenerate it non-interactively. Callers are
+// expected to have already stripped any "secret": true variables out of
+// answers, since this is written to disk in cleartext.
+func SaveAnswers(rawURL string, answers map[string]interface{}) error {
+	path, err := AnswersPath(rawURL)
+	if err != nil {
+		return err
+	}
+
+	encoded, err := json.MarshalIndent(answers, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding answers: %v", err)
+	}
+
+	if err := os.WriteFile(path, encoded, 0600); err != nil {
+		return fmt.Errorf("error writing answers to %s: %v", path, err)
+	}
+	return nil
+}
+
+// LoadAnswers reads back the answers previously recorded by SaveAnswers.
+func LoadAnswers(rawURL string) (map[string]interface{}, error) {
+	path, err := AnswersPath(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading recorded answers for %s: %v", rawURL, err)
+	}
+
+	var answers map[string]interface{}
+	if err := json.Unmarshal(content, &answers); err != nil {
+		return nil, fmt.Errorf("error parsing recorded answers %s: %v", path, err)
+	}
+	return answers, nil
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// loadConfigFile reads a --config-file of answers, keyed by variable name.
+// The format is inferred from the extension: ".yaml"/".yml" is parsed as
+// YAML, anything else as JSON. An empty path returns an empty map.
+func loadConfigFile(path string) (map[string]interface{}, error) {
+	answers := make(map[string]interface{})
+	if path == "" {
+		return answers, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading config file %s: %v", path, err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(content, &answers); err != nil {
+			return nil, fmt.Errorf("error parsing config file %s as YAML: %v", path, err)
+		}
+	default:
+		if err := json.Unmarshal(content, &answers); err != nil {
+			return nil, fmt.Errorf("error parsing config file %s as JSON: %v", path, err)
+		}
+	}
+
+	return answers, nil
+}
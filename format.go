@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"go/format"
+	"path/filepath"
+
+	"golang.org/x/tools/imports"
+)
+
+// formatGoSource gofmt-formats and import-cleans Go source rendered from a
+// template, so templates that produce Go code don't need a post_gen hook
+// just to run gofmt. It returns an error only when content doesn't parse
+// as Go, so callers can fall back to the raw rendered output.
+func formatGoSource(path string, content []byte) ([]byte, error) {
+	if _, err := format.Source(content); err != nil {
+		return nil, err
+	}
+	return imports.Process(path, content, nil)
+}
+
+// maybeFormatGo rewrites outputPath with gofmt/goimports output if
+// formatGo is enabled and the path looks like Go source, warning and
+// leaving the raw rendered content in place if it doesn't parse.
+func maybeFormatGo(outputPath string, content []byte, formatGo bool) []byte {
+	if !formatGo || filepath.Ext(outputPath) != ".go" {
+		return content
+	}
+
+	formatted, err := formatGoSource(outputPath, content)
+	if err != nil {
+		fmt.Printf("warning: could not gofmt %s, keeping raw output: %v\n", outputPath, err)
+		return content
+	}
+	return formatted
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Mr-Destructive/gophycutter/hooks"
+)
+
+// Context is the template context passed through generation: a
+// "cookiecutter" key holding the answered variables, matching the shape
+// produced by generateContext.
+type Context = map[string]interface{}
+
+// Options controls how Generate resolves variables and where it writes
+// output, letting the core generation logic run outside of an interactive
+// terminal (e.g. in CI) and be embedded by other Go programs.
+type Options struct {
+	// NoInput accepts the default for every variable instead of prompting.
+	NoInput bool
+	// ConfigFile, if set, loads answers from a YAML or JSON file, keyed by
+	// variable name. Values from ConfigFile are applied before prompting,
+	// so remaining unanswered variables are still prompted unless NoInput
+	// is also set.
+	ConfigFile string
+	// ExtraContext overrides individual variables by name, taking
+	// precedence over ConfigFile and defaults. Values are plain strings,
+	// converted according to each variable's declared (or inferred) type.
+	ExtraContext map[string]string
+	// OutputDir, if set, is used instead of the current directory joined
+	// with the template's base name.
+	OutputDir string
+	// FormatGo runs gofmt/goimports over any rendered file ending in .go.
+	FormatGo bool
+}
+
+// Generate runs the full generation pipeline against a template directory
+// that has already been resolved to a local path (cloned, extracted, or
+// already on disk): it builds the context, resolves every variable
+// according to opts, runs hooks, and writes the rendered output.
+func Generate(templateDir string, opts Options) (map[string]interface{}, error) {
+	configFile := filepath.Join(templateDir, configFileName)
+	context, err := generateContext(configFile)
+	if err != nil {
+		return nil, fmt.Errorf("error generating context: %v", err)
+	}
+
+	data := context["cookiecutter"].(map[string]interface{})
+
+	fileAnswers, err := loadConfigFile(opts.ConfigFile)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range data {
+		if isPrivateKey(key) {
+			continue
+		}
+
+		if extra, ok := opts.ExtraContext[key]; ok {
+			converted, err := convertAnswer(value, extra)
+			if err != nil {
+				return nil, fmt.Errorf("error applying --extra-context %s: %v", key, err)
+			}
+			data[key] = converted
+			continue
+		}
+
+		if answer, ok := fileAnswers[key]; ok {
+			data[key] = answer
+			continue
+		}
+
+		if opts.NoInput {
+			data[key] = defaultAnswer(value)
+			continue
+		}
+
+		answer, err := promptVariable(key, value)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %v", key, err)
+		}
+		data[key] = answer
+	}
+	context["cookiecutter"] = data
+	delims := extractDelimiters(data)
+
+	outputDir := opts.OutputDir
+	if outputDir == "" {
+		currentDir, err := os.Getwd()
+		if err != nil {
+			return nil, fmt.Errorf("error getting current directory: %v", err)
+		}
+		outputDir = filepath.Join(currentDir, filepath.Base(templateDir))
+	}
+
+	hooksDir := filepath.Join(templateDir, hooks.DirName)
+	if err := hooks.RunPreGen(hooksDir, delims.left, delims.right, context); err != nil {
+		return nil, fmt.Errorf("error running pre_gen hook: %v", err)
+	}
+
+	templateRoot, err := findTemplateRoot(templateDir, delims)
+	if err != nil {
+		return nil, fmt.Errorf("error locating template root: %v", err)
+	}
+
+	if err := generateFiles(context, templateRoot, outputDir, delims, opts.FormatGo); err != nil {
+		return nil, fmt.Errorf("error generating files: %v", err)
+	}
+
+	if err := hooks.RunPostGen(hooksDir, outputDir, delims.left, delims.right, context); err != nil {
+		if rmErr := os.RemoveAll(outputDir); rmErr != nil {
+			return nil, fmt.Errorf("error running post_gen hook: %v (and rolling back output directory: %v)", err, rmErr)
+		}
+		return nil, fmt.Errorf("error running post_gen hook: %v", err)
+	}
+
+	return data, nil
+}
+
+// defaultAnswer resolves the default used for --no-input: the declared
+// "default" for a rich variable (falling back to the first choice, same
+// as promptChoice does on empty input, for the common case of a choices
+// variable with no redundant explicit default), or the raw value itself
+// for the classic flat key/value form.
+func defaultAnswer(raw interface{}) interface{} {
+	if spec, ok := parseVariableSpec(raw); ok {
+		if spec.Default == nil && len(spec.Choices) > 0 {
+			return spec.Choices[0]
+		}
+		return spec.Default
+	}
+	return raw
+}
+
+// convertAnswer converts a string answer (from --extra-context or a config
+// file) the same way an interactive prompt would, honoring a rich
+// variable's declared type.
+func convertAnswer(raw interface{}, value string) (interface{}, error) {
+	if spec, ok := parseVariableSpec(raw); ok {
+		return convertValue(spec, value)
+	}
+	return convertToType(raw, value)
+}
+
+// secretKeys returns the set of cookiecutter.json variable names declared
+// with "secret": true, so callers can keep their answered values out of
+// persisted state such as the replay store.
+func secretKeys(templateDir string) (map[string]bool, error) {
+	configFile := filepath.Join(templateDir, configFileName)
+	context, err := generateContext(configFile)
+	if err != nil {
+		return nil, fmt.Errorf("error generating context: %v", err)
+	}
+
+	data := context["cookiecutter"].(map[string]interface{})
+	keys := make(map[string]bool)
+	for key, value := range data {
+		if spec, ok := parseVariableSpec(value); ok && spec.Secret {
+			keys[key] = true
+		}
+	}
+	return keys, nil
+}
@@ -0,0 +1,184 @@
+// Package hooks implements gophycutter's pre/post-generation hook
+// subsystem, mirroring the hook model established by upstream Cookiecutter.
+//
+// A template may ship a "hooks" directory, sibling to cookiecutter.json,
+// containing a "pre_gen_project" and/or "post_gen_project" script. The
+// script's extension selects the interpreter used to run it, and its
+// contents are rendered as a Go template against the answered context
+// before execution, so hooks can embed answered variables.
+package hooks
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+const (
+	// DirName is the directory, sibling to cookiecutter.json, that holds
+	// hook scripts.
+	DirName = "hooks"
+
+	// PreGenName is the base name of the hook run before files are
+	// generated.
+	PreGenName = "pre_gen_project"
+
+	// PostGenName is the base name of the hook run after files are
+	// generated.
+	PostGenName = "post_gen_project"
+)
+
+// interpreters maps a hook script extension to the command used to run it.
+// The command is run as: <cmd> <args...> <scriptPath>.
+var interpreters = map[string][]string{
+	".go":  {"go", "run"},
+	".sh":  {"sh"},
+	".py":  {"python3"},
+	".ps1": {"powershell"},
+}
+
+// interpreterExts is the fixed probe order find uses to look for a hook
+// script. It must list the same extensions as interpreters, but as a slice
+// rather than a map so probing is deterministic.
+var interpreterExts = []string{".go", ".sh", ".py", ".ps1"}
+
+// find locates a hook with the given base name in hooksDir, trying each
+// known interpreter extension (in a fixed order) before falling back to a
+// plain executable with no extension. It returns an empty path if no
+// matching hook exists, and errors out if more than one extension variant
+// is present, rather than silently picking one.
+func find(hooksDir, name string) (string, error) {
+	var matches []string
+	for _, ext := range interpreterExts {
+		path := filepath.Join(hooksDir, name+ext)
+		if _, err := os.Stat(path); err == nil {
+			matches = append(matches, path)
+		}
+	}
+	if len(matches) > 1 {
+		return "", fmt.Errorf("ambiguous hook %q: found multiple candidates %v, keep only one", name, matches)
+	}
+	if len(matches) == 1 {
+		return matches[0], nil
+	}
+
+	path := filepath.Join(hooksDir, name)
+	if info, err := os.Stat(path); err == nil {
+		if info.Mode()&0111 == 0 {
+			return "", fmt.Errorf("hook %s is not executable", path)
+		}
+		return path, nil
+	}
+
+	return "", nil
+}
+
+// command builds the exec.Cmd used to run a rendered hook script, selecting
+// an interpreter by the script's extension, or invoking it directly if it
+// has none (it is expected to be an executable in that case).
+func command(scriptPath string) (*exec.Cmd, error) {
+	ext := filepath.Ext(scriptPath)
+	if ext == "" {
+		return exec.Command(scriptPath), nil
+	}
+
+	parts, ok := interpreters[ext]
+	if !ok {
+		return nil, fmt.Errorf("no interpreter registered for hook extension %q", ext)
+	}
+
+	args := append(append([]string{}, parts[1:]...), scriptPath)
+	return exec.Command(parts[0], args...), nil
+}
+
+// render parses a hook script as a Go template, using the same action
+// delimiters as the rest of the template, and executes it against context,
+// writing the result to a temp file that preserves the original extension
+// so interpreter detection still works.
+func render(scriptPath, delimLeft, delimRight string, context map[string]interface{}) (string, error) {
+	content, err := os.ReadFile(scriptPath)
+	if err != nil {
+		return "", fmt.Errorf("error reading hook %s: %v", scriptPath, err)
+	}
+
+	tmpl, err := template.New(filepath.Base(scriptPath)).Delims(delimLeft, delimRight).Parse(string(content))
+	if err != nil {
+		return "", fmt.Errorf("error parsing hook template %s: %v", scriptPath, err)
+	}
+
+	var rendered bytes.Buffer
+	if err := tmpl.Execute(&rendered, context); err != nil {
+		return "", fmt.Errorf("error rendering hook template %s: %v", scriptPath, err)
+	}
+
+	tmpFile, err := os.CreateTemp("", "gophycutter-hook-*"+filepath.Ext(scriptPath))
+	if err != nil {
+		return "", fmt.Errorf("error creating temp hook file: %v", err)
+	}
+	defer tmpFile.Close()
+
+	if _, err := tmpFile.Write(rendered.Bytes()); err != nil {
+		return "", fmt.Errorf("error writing temp hook file: %v", err)
+	}
+	if err := os.Chmod(tmpFile.Name(), 0755); err != nil {
+		return "", fmt.Errorf("error making temp hook file executable: %v", err)
+	}
+
+	return tmpFile.Name(), nil
+}
+
+// run renders and executes the named hook, if present, with workDir as its
+// working directory. It is a no-op if the hook doesn't exist in hooksDir.
+func run(hooksDir, name, workDir, delimLeft, delimRight string, context map[string]interface{}) error {
+	scriptPath, err := find(hooksDir, name)
+	if err != nil {
+		return err
+	}
+	if scriptPath == "" {
+		return nil
+	}
+
+	renderedPath, err := render(scriptPath, delimLeft, delimRight, context)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(renderedPath)
+
+	cmd, err := command(renderedPath)
+	if err != nil {
+		return err
+	}
+	cmd.Dir = workDir
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("hook %s failed: %v", strings.TrimSuffix(name, filepath.Ext(name)), err)
+	}
+	return nil
+}
+
+// RunPreGen runs the pre_gen_project hook, if present, in a throwaway temp
+// directory since the output directory doesn't exist yet. Callers should
+// abort generation if it returns an error. delimLeft/delimRight are the
+// same action delimiters used to render the rest of the template.
+func RunPreGen(hooksDir, delimLeft, delimRight string, context map[string]interface{}) error {
+	tempDir, err := os.MkdirTemp("", "gophycutter-pregen-")
+	if err != nil {
+		return fmt.Errorf("error creating pre_gen work directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	return run(hooksDir, PreGenName, tempDir, delimLeft, delimRight, context)
+}
+
+// RunPostGen runs the post_gen_project hook, if present, with outputDir as
+// its working directory so the hook can act on generated files. Callers
+// should roll back (delete) outputDir if it returns an error.
+func RunPostGen(hooksDir, outputDir, delimLeft, delimRight string, context map[string]interface{}) error {
+	return run(hooksDir, PostGenName, outputDir, delimLeft, delimRight, context)
+}
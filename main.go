@@ -4,15 +4,19 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"text/template"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Mr-Destructive/gophycutter/cache"
+	"github.com/Mr-Destructive/gophycutter/hooks"
 )
 
 const (
 	configFileName = "cookiecutter.json"
-	postGenDirName = "post_gen"
 )
 
 // generateContext reads and decodes the JSON configuration file into a context map.
@@ -35,33 +39,35 @@ func generateContext(configFile string) (map[string]interface{}, error) {
 	return context, nil
 }
 
-// generateFiles processes the input directory and generates files in the output directory.
-func generateFiles(context map[string]interface{}, inputDir, outputDir string) error {
+// generateFiles walks templateRoot, the directory containing the actual
+// templated tree (as opposed to repo-level metadata like cookiecutter.json
+// or hooks/), and generates files into outputDir.
+func generateFiles(context map[string]interface{}, templateRoot, outputDir string, delims delimiters, formatGo bool) error {
 	if err := os.MkdirAll(outputDir, os.ModePerm); err != nil {
 		return fmt.Errorf("error creating output directory: %v", err)
 	}
 
-	err := filepath.Walk(inputDir, func(path string, info os.FileInfo, err error) error {
+	err := filepath.Walk(templateRoot, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return fmt.Errorf("error accessing file or directory: %v", err)
 		}
 
-		if info.IsDir() && info.Name() == ".git" {
+		if info.IsDir() && (info.Name() == ".git" || path == filepath.Join(templateRoot, hooks.DirName)) {
 			return filepath.SkipDir
 		}
 
-		relativePath, err := filepath.Rel(inputDir, path)
+		relativePath, err := filepath.Rel(templateRoot, path)
 		if err != nil {
 			return fmt.Errorf("error getting relative path: %v", err)
 		}
-		outputPath := filepath.Join(outputDir, renderTemplate(relativePath, context))
+		outputPath := filepath.Join(outputDir, renderTemplate(relativePath, context, delims))
 
 		if info.IsDir() {
 			if err := os.MkdirAll(outputPath, os.ModePerm); err != nil {
 				return fmt.Errorf("error creating directory: %v", err)
 			}
 		} else if info.Name() != configFileName {
-			if err := processFile(path, outputPath, context); err != nil {
+			if err := processFile(path, outputPath, context, delims, formatGo); err != nil {
 				return err
 			}
 		}
@@ -77,8 +83,8 @@ func generateFiles(context map[string]interface{}, inputDir, outputDir string) e
 }
 
 // renderTemplate renders a template with the provided context.
-func renderTemplate(input string, context map[string]interface{}) string {
-	tmpl, err := template.New("").Parse(input)
+func renderTemplate(input string, context map[string]interface{}, delims delimiters) string {
+	tmpl, err := template.New("").Delims(delims.left, delims.right).Parse(input)
 	if err != nil {
 		fmt.Printf("error parsing template: %v\n", err)
 		return input
@@ -92,14 +98,15 @@ func renderTemplate(input string, context map[string]interface{}) string {
 	return renderedContent.String()
 }
 
-// processFile reads, processes, and writes a file template.
-func processFile(inputPath, outputPath string, context map[string]interface{}) error {
+// processFile reads, processes, and writes a file template, optionally
+// gofmt/goimports-formatting the result if it renders to a .go file.
+func processFile(inputPath, outputPath string, context map[string]interface{}, delims delimiters, formatGo bool) error {
 	content, err := os.ReadFile(inputPath)
 	if err != nil {
 		return fmt.Errorf("error reading input file: %v", err)
 	}
 
-	tmpl, err := template.New("").Parse(string(content))
+	tmpl, err := template.New("").Delims(delims.left, delims.right).Parse(string(content))
 	if err != nil {
 		return fmt.Errorf("error parsing template: %v", err)
 	}
@@ -109,113 +116,178 @@ func processFile(inputPath, outputPath string, context map[string]interface{}) e
 		return fmt.Errorf("error rendering template: %v", err)
 	}
 
-	outputPath = renderTemplate(outputPath, context)
+	outputPath = renderTemplate(outputPath, context, delims)
+	output := maybeFormatGo(outputPath, []byte(renderedContent.String()), formatGo)
 
-	if err := os.WriteFile(outputPath, []byte(renderedContent.String()), 0644); err != nil {
+	if err := os.WriteFile(outputPath, output, 0644); err != nil {
 		return fmt.Errorf("error writing output file: %v", err)
 	}
 
 	return nil
 }
 
-// convertToType converts a string value to the specified type.
-func convertToType(expectedType interface{}, value string) interface{} {
-	switch expectedType.(type) {
-	case string:
-		return value
-	case int:
-		var v int
-		fmt.Sscan(value, &v)
-		return v
-	case bool:
-		var v bool
-		fmt.Sscan(value, &v)
-		return v
-	case float64:
-		var v float64
-		fmt.Sscan(value, &v)
-		return v
-	default:
-		return value
-	}
+// supportedSchemes lists the template source schemes cache.Resolve knows
+// how to fetch: http(s) for .zip/.tar.gz archives and plain git-over-http,
+// plus git/ssh for git remotes.
+var supportedSchemes = map[string]bool{
+	"http":  true,
+	"https": true,
+	"git":   true,
+	"ssh":   true,
 }
 
-// getUserInput reads user input from the console.
-func getUserInput() string {
-	var val string
-	fmt.Scanln(&val)
-	return val
+// scpLikeGitRemote matches the scp-like shorthand git remotes support
+// (e.g. "git@github.com:user/repo.git"), which has no "scheme://" prefix.
+var scpLikeGitRemote = regexp.MustCompile(`^[\w.-]+@[\w.-]+:`)
+
+// templateScheme extracts the scheme inputDir was given with, recognizing
+// both standard "scheme://..." URLs and the scp-like git remote shorthand.
+// It returns ok=false for a plain local path.
+func templateScheme(inputDir string) (scheme string, ok bool) {
+	if idx := strings.Index(inputDir, "://"); idx != -1 && !strings.ContainsAny(inputDir[:idx], `/\`) {
+		return inputDir[:idx], true
+	}
+	if scpLikeGitRemote.MatchString(inputDir) {
+		return "ssh", true
+	}
+	return "", false
 }
 
-// runPostGenScripts executes post-generation scripts in the output directory.
-func runPostGenScripts(outputDir string) error {
-	postGenDir := filepath.Join(outputDir, postGenDirName)
-	_, err := os.Stat(postGenDir)
-	if os.IsNotExist(err) {
-		return nil
-	} else if err != nil {
-		return fmt.Errorf("error checking post_gen directory: %v", err)
+// resolveTemplateDir makes the template available on disk: local paths are
+// used as-is, and URLs (git repos, or .zip/.tar.gz archives) are fetched
+// into the ~/.gophycutter cache, pinned to checkout if given.
+func resolveTemplateDir(inputDir, checkout string) (string, error) {
+	scheme, ok := templateScheme(inputDir)
+	if !ok {
+		return inputDir, nil
+	}
+	if !supportedSchemes[scheme] {
+		return "", fmt.Errorf("unsupported template source scheme %q in %s", scheme, inputDir)
 	}
+	return cache.Resolve(inputDir, checkout)
+}
 
-	files, err := filepath.Glob(filepath.Join(postGenDir, "*.go"))
-	if err != nil {
-		return fmt.Errorf("error listing files in post_gen directory: %v", err)
-	}
-	for _, file := range files {
-		cmd := exec.Command("go", "run", file)
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
-		if err := cmd.Run(); err != nil {
-			return fmt.Errorf("error running post-gen script %s: %v", file, err)
+// redactSecrets returns a copy of answers with every key in secrets
+// removed, so values from "secret": true variables are never written to
+// the replay store. A redacted answer falls back to its declared default
+// (or zero value) on replay, same as any other unanswered variable.
+func redactSecrets(answers map[string]interface{}, secrets map[string]bool) map[string]interface{} {
+	redacted := make(map[string]interface{}, len(answers))
+	for key, value := range answers {
+		if secrets[key] {
+			continue
 		}
+		redacted[key] = value
 	}
-	return nil
+	return redacted
 }
 
 func main() {
-	var inputDir string
-	fmt.Println("Enter the path to the directory:")
-	fmt.Scanln(&inputDir)
-	repoName := inputDir
-
-	if strings.HasPrefix(inputDir, "https://") {
-		repoUrl := strings.TrimRight(inputDir, "/")
-		repoName = filepath.Base(repoUrl)
-		err := exec.Command("git", "clone", inputDir).Run()
-		if err != nil {
-			fmt.Printf("error cloning repo: %v\n", err)
-			return
-		}
-	}
-	configFile := filepath.Join(repoName, configFileName)
-	context, err := generateContext(configFile)
-	if err != nil {
-		fmt.Printf("error generating context: %v\n", err)
-		return
-	}
+	var opts Options
+	var checkout string
+	var extraContext []string
+
+	rootCmd := &cobra.Command{
+		Use:   "gophycutter <template>",
+		Short: "Generate a project from a cookiecutter-style template",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			inputDir := args[0]
+
+			parsed, err := parseExtraContext(extraContext)
+			if err != nil {
+				return err
+			}
+			opts.ExtraContext = parsed
+
+			templateDir, err := resolveTemplateDir(inputDir, checkout)
+			if err != nil {
+				return err
+			}
 
-	data := context["cookiecutter"].(map[string]interface{})
-	for key, value := range data {
-		fmt.Printf("%v (%v): ", key, value)
-		val := getUserInput()
-		data[key] = convertToType(value, val)
+			answers, err := Generate(templateDir, opts)
+			if err != nil {
+				return err
+			}
+
+			if _, ok := templateScheme(inputDir); ok {
+				secrets, err := secretKeys(templateDir)
+				if err != nil {
+					fmt.Printf("warning: error recording answers for replay: %v\n", err)
+				} else if err := cache.SaveAnswers(inputDir, redactSecrets(answers, secrets)); err != nil {
+					fmt.Printf("warning: error recording answers for replay: %v\n", err)
+				}
+			}
+			return nil
+		},
 	}
-	context["cookiecutter"] = data
 
-	currentDir, err := os.Getwd()
-	if err != nil {
-		fmt.Printf("error getting current directory: %v\n", err)
-		return
+	rootCmd.Flags().BoolVar(&opts.NoInput, "no-input", false, "accept default values for all variables instead of prompting")
+	rootCmd.Flags().StringVar(&opts.ConfigFile, "config-file", "", "YAML or JSON file of answers to use instead of prompting")
+	rootCmd.Flags().StringArrayVar(&extraContext, "extra-context", nil, "override a variable, as key=value (may be repeated)")
+	rootCmd.Flags().StringVar(&opts.OutputDir, "output-dir", "", "directory to write the generated project into")
+	rootCmd.Flags().StringVar(&checkout, "checkout", "", "branch, tag, or commit to check out after cloning")
+	rootCmd.Flags().BoolVar(&opts.FormatGo, "format-go", true, "gofmt/goimports any rendered .go file")
+
+	rootCmd.AddCommand(newReplayCmd())
+
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
 	}
-	outputDir := filepath.Join(currentDir, filepath.Base(repoName))
-	err = generateFiles(context, inputDir, outputDir)
-	if err != nil {
-		fmt.Printf("error generating files: %v\n", err)
-		return
+}
+
+// newReplayCmd builds the `replay` subcommand, which regenerates a
+// previously-used template non-interactively from the answers recorded by
+// the last `gophycutter <template>` run, entirely offline.
+func newReplayCmd() *cobra.Command {
+	var outputDir string
+	var formatGo bool
+
+	cmd := &cobra.Command{
+		Use:   "replay <template>",
+		Short: "Re-run a template non-interactively from its last recorded answers",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			inputURL := args[0]
+
+			templateDir, cached, err := cache.Lookup(inputURL)
+			if err != nil {
+				return err
+			}
+			if !cached {
+				return fmt.Errorf("no cached template for %s, run a normal generation first", inputURL)
+			}
+
+			answersPath, err := cache.AnswersPath(inputURL)
+			if err != nil {
+				return err
+			}
+
+			_, err = Generate(templateDir, Options{
+				NoInput:    true,
+				ConfigFile: answersPath,
+				OutputDir:  outputDir,
+				FormatGo:   formatGo,
+			})
+			return err
+		},
 	}
-	err = runPostGenScripts(outputDir)
-	if err != nil {
-		fmt.Printf("error running post-gen scripts: %v\n", err)
-		return
+
+	cmd.Flags().StringVar(&outputDir, "output-dir", "", "directory to write the generated project into")
+	cmd.Flags().BoolVar(&formatGo, "format-go", true, "gofmt/goimports any rendered .go file")
+	return cmd
+}
+
+// parseExtraContext parses "key=value" pairs from --extra-context into a map.
+func parseExtraContext(pairs []string) (map[string]string, error) {
+	extra := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid --extra-context %q, expected key=value", pair)
+		}
+		extra[key] = value
 	}
+	return extra, nil
 }
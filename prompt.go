@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// getUserInput reads a line of user input from the console.
+func getUserInput() string {
+	reader := bufio.NewReader(os.Stdin)
+	val, _ := reader.ReadString('\n')
+	return strings.TrimSpace(val)
+}
+
+// getSecretInput reads user input without echoing it to the terminal, for
+// variables declared with "secret": true.
+func getSecretInput() (string, error) {
+	val, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Println()
+	if err != nil {
+		return "", fmt.Errorf("error reading secret input: %v", err)
+	}
+	return strings.TrimSpace(string(val)), nil
+}
+
+// promptVariable prompts for a single cookiecutter variable, re-prompting
+// until the answer satisfies the declared type, regex, and choices.
+func promptVariable(key string, raw interface{}) (interface{}, error) {
+	spec, isRich := parseVariableSpec(raw)
+	if !isRich {
+		for {
+			fmt.Printf("%v (%v): ", key, raw)
+			val := getUserInput()
+			if val == "" {
+				return raw, nil
+			}
+
+			converted, err := convertToType(raw, val)
+			if err != nil {
+				fmt.Printf("invalid input: %v, please try again\n", err)
+				continue
+			}
+			return converted, nil
+		}
+	}
+
+	if spec.Help != "" {
+		fmt.Printf("%s\n", spec.Help)
+	}
+
+	if len(spec.Choices) > 0 && spec.Type != "multiselect" {
+		return promptChoice(key, spec)
+	}
+
+	for {
+		fmt.Printf("%v (%v): ", key, spec.Default)
+
+		var val string
+		if spec.Secret {
+			secret, err := getSecretInput()
+			if err != nil {
+				return nil, err
+			}
+			val = secret
+		} else {
+			val = getUserInput()
+		}
+
+		if val == "" {
+			if spec.Default != nil {
+				return spec.Default, nil
+			}
+			val = ""
+		}
+
+		converted, err := convertValue(spec, val)
+		if err != nil {
+			fmt.Printf("invalid input: %v, please try again\n", err)
+			continue
+		}
+		return converted, nil
+	}
+}
+
+// promptChoice renders a numbered menu for a variable with a fixed set of
+// choices, mirroring Cookiecutter's "select N" prompt.
+func promptChoice(key string, spec variableSpec) (interface{}, error) {
+	fmt.Printf("Select %s:\n", key)
+	for i, choice := range spec.Choices {
+		fmt.Printf("  %d - %v\n", i+1, choice)
+	}
+
+	for {
+		fmt.Printf("Choose from %d options [1]: ", len(spec.Choices))
+		val := getUserInput()
+		if val == "" {
+			val = "1"
+		}
+
+		idx, err := strconv.Atoi(val)
+		if err != nil || idx < 1 || idx > len(spec.Choices) {
+			fmt.Printf("invalid input: %q is not a valid choice number (1-%d), please try again\n", val, len(spec.Choices))
+			continue
+		}
+		return spec.Choices[idx-1], nil
+	}
+}
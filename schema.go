@@ -0,0 +1,156 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// variableSpec describes a single cookiecutter.json variable declared using
+// the rich object form, e.g.:
+//
+//	"project_slug": {
+//	  "type": "string",
+//	  "default": "my-project",
+//	  "regex": "^[a-z][a-z0-9-]*$",
+//	  "help": "Used as the Go module name"
+//	}
+type variableSpec struct {
+	Type    string        `json:"type"`
+	Default interface{}   `json:"default"`
+	Choices []interface{} `json:"choices"`
+	Regex   string        `json:"regex"`
+	Help    string        `json:"help"`
+	Secret  bool          `json:"secret"`
+}
+
+// parseVariableSpec inspects a raw cookiecutter.json value and, if it looks
+// like the rich object form (i.e. it declares a "type"), decodes it into a
+// variableSpec. Plain scalars, lists, and maps without a "type" key are
+// treated as the classic flat key/value form and are returned unchanged.
+func parseVariableSpec(raw interface{}) (variableSpec, bool) {
+	obj, ok := raw.(map[string]interface{})
+	if !ok {
+		return variableSpec{}, false
+	}
+	if _, ok := obj["type"]; !ok {
+		return variableSpec{}, false
+	}
+
+	encoded, err := json.Marshal(obj)
+	if err != nil {
+		return variableSpec{}, false
+	}
+
+	var spec variableSpec
+	if err := json.Unmarshal(encoded, &spec); err != nil {
+		return variableSpec{}, false
+	}
+	return spec, true
+}
+
+// convertValue parses raw user input according to the variable's declared
+// type, validating against regex and choices where applicable. It replaces
+// the old convertToType, which silently produced zero values on bad input.
+func convertValue(spec variableSpec, value string) (interface{}, error) {
+	if spec.Regex != "" {
+		matched, err := regexp.MatchString(spec.Regex, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex %q: %v", spec.Regex, err)
+		}
+		if !matched {
+			return nil, fmt.Errorf("%q does not match pattern %q", value, spec.Regex)
+		}
+	}
+
+	switch spec.Type {
+	case "list":
+		var v []interface{}
+		if err := json.Unmarshal([]byte(value), &v); err != nil {
+			return nil, fmt.Errorf("%q is not a valid JSON array: %v", value, err)
+		}
+		return v, nil
+	case "multiselect":
+		return parseMultiselect(spec.Choices, value)
+	default:
+		return parseScalar(spec.Type, value)
+	}
+}
+
+// parseScalar parses value as the named scalar type ("string", "int",
+// "float", or "bool"; "" is treated as "string"), returning an error
+// instead of silently coercing bad input to a zero value.
+func parseScalar(typeName, value string) (interface{}, error) {
+	switch typeName {
+	case "", "string":
+		return value, nil
+	case "int":
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a valid int: %v", value, err)
+		}
+		return v, nil
+	case "float":
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a valid float: %v", value, err)
+		}
+		return v, nil
+	case "bool":
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a valid bool: %v", value, err)
+		}
+		return v, nil
+	default:
+		return nil, fmt.Errorf("unknown variable type %q", typeName)
+	}
+}
+
+// parseMultiselect parses a comma-separated list of 1-based choice indexes
+// (e.g. "1,3") into the selected choice values.
+func parseMultiselect(choices []interface{}, value string) ([]interface{}, error) {
+	parts := strings.Split(value, ",")
+	selected := make([]interface{}, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		idx, err := strconv.Atoi(part)
+		if err != nil || idx < 1 || idx > len(choices) {
+			return nil, fmt.Errorf("%q is not a valid choice number (1-%d)", part, len(choices))
+		}
+		selected = append(selected, choices[idx-1])
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("at least one choice must be selected")
+	}
+	return selected, nil
+}
+
+// convertToType converts a string value to the type of expectedType, for
+// the classic flat key/value form of a cookiecutter.json variable that
+// doesn't carry an explicit schema. It returns an error instead of
+// silently coercing bad input to a zero value, same as convertValue does
+// for the rich object form.
+func convertToType(expectedType interface{}, value string) (interface{}, error) {
+	return parseScalar(inferredType(expectedType), value)
+}
+
+// inferredType maps the Go type of a flat cookiecutter.json value to the
+// variable type name convertValue/parseScalar understand.
+func inferredType(expectedType interface{}) string {
+	switch expectedType.(type) {
+	case int:
+		return "int"
+	case float64:
+		return "float"
+	case bool:
+		return "bool"
+	default:
+		return "string"
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func decodeRaw(t *testing.T, jsonText string) interface{} {
+	t.Helper()
+	var raw interface{}
+	if err := json.Unmarshal([]byte(jsonText), &raw); err != nil {
+		t.Fatalf("error decoding test fixture: %v", err)
+	}
+	return raw
+}
+
+func TestParseVariableSpec(t *testing.T) {
+	cases := []struct {
+		name    string
+		json    string
+		wantOk  bool
+		wantDef interface{}
+	}{
+		{"rich object with type", `{"type": "string", "default": "demo"}`, true, "demo"},
+		{"flat string", `"demo"`, false, nil},
+		{"flat bool", `true`, false, nil},
+		{"map without type key", `{"default": "demo"}`, false, nil},
+		{"flat list", `["a", "b"]`, false, nil},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			raw := decodeRaw(t, tc.json)
+			spec, ok := parseVariableSpec(raw)
+			if ok != tc.wantOk {
+				t.Fatalf("parseVariableSpec(%s) ok = %v, want %v", tc.json, ok, tc.wantOk)
+			}
+			if ok && !reflect.DeepEqual(spec.Default, tc.wantDef) {
+				t.Fatalf("parseVariableSpec(%s) default = %v, want %v", tc.json, spec.Default, tc.wantDef)
+			}
+		})
+	}
+}
+
+func TestParseScalar(t *testing.T) {
+	cases := []struct {
+		typeName string
+		value    string
+		want     interface{}
+		wantErr  bool
+	}{
+		{"", "hello", "hello", false},
+		{"string", "hello", "hello", false},
+		{"int", "42", 42, false},
+		{"int", "not-a-number", nil, true},
+		{"float", "3.14", 3.14, false},
+		{"float", "nope", nil, true},
+		{"bool", "true", true, false},
+		{"bool", "nope", nil, true},
+		{"unknown", "x", nil, true},
+	}
+
+	for _, tc := range cases {
+		got, err := parseScalar(tc.typeName, tc.value)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("parseScalar(%q, %q) = %v, want error", tc.typeName, tc.value, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseScalar(%q, %q) returned unexpected error: %v", tc.typeName, tc.value, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("parseScalar(%q, %q) = %v, want %v", tc.typeName, tc.value, got, tc.want)
+		}
+	}
+}
+
+func TestConvertValueRegexAndChoices(t *testing.T) {
+	spec := variableSpec{Type: "string", Regex: "^[a-z-]+$"}
+	if _, err := convertValue(spec, "Bad Slug"); err == nil {
+		t.Fatal("convertValue accepted a value that doesn't match the regex")
+	}
+	got, err := convertValue(spec, "good-slug")
+	if err != nil {
+		t.Fatalf("convertValue rejected a value that matches the regex: %v", err)
+	}
+	if got != "good-slug" {
+		t.Fatalf("convertValue = %v, want good-slug", got)
+	}
+
+	listSpec := variableSpec{Type: "list"}
+	v, err := convertValue(listSpec, `["a", "b"]`)
+	if err != nil {
+		t.Fatalf("convertValue rejected a valid JSON array: %v", err)
+	}
+	if !reflect.DeepEqual(v, []interface{}{"a", "b"}) {
+		t.Fatalf("convertValue = %v, want [a b]", v)
+	}
+	if _, err := convertValue(listSpec, `not json`); err == nil {
+		t.Fatal("convertValue accepted an invalid JSON array")
+	}
+}
+
+func TestParseMultiselect(t *testing.T) {
+	choices := []interface{}{"red", "green", "blue"}
+
+	got, err := parseMultiselect(choices, "1,3")
+	if err != nil {
+		t.Fatalf("parseMultiselect returned unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, []interface{}{"red", "blue"}) {
+		t.Fatalf("parseMultiselect = %v, want [red blue]", got)
+	}
+
+	if _, err := parseMultiselect(choices, "5"); err == nil {
+		t.Fatal("parseMultiselect accepted an out-of-range index")
+	}
+	if _, err := parseMultiselect(choices, ""); err == nil {
+		t.Fatal("parseMultiselect accepted an empty selection")
+	}
+}
+
+func TestConvertToTypeInfersFromFlatDefault(t *testing.T) {
+	if got, err := convertToType(0, "42"); err != nil || got != 42 {
+		t.Fatalf("convertToType(int default) = (%v, %v), want (42, nil)", got, err)
+	}
+	if got, err := convertToType(0, "nope"); err == nil {
+		t.Fatalf("convertToType(int default) accepted invalid input: %v", got)
+	}
+	if got, err := convertToType(false, "true"); err != nil || got != true {
+		t.Fatalf("convertToType(bool default) = (%v, %v), want (true, nil)", got, err)
+	}
+	if got, err := convertToType("placeholder", "anything"); err != nil || got != "anything" {
+		t.Fatalf("convertToType(string default) = (%v, %v), want (anything, nil)", got, err)
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// delimitersKey is the cookiecutter.json key (alongside regular variables)
+// used to override the template action delimiters, e.g.
+// "_delimiters": ["[[", "]]"] for templates whose rendered files (Go
+// source, Helm charts, ...) already use "{{ }}" themselves.
+const delimitersKey = "_delimiters"
+
+// delimiters holds the left/right action delimiters used to parse both
+// file contents and file/directory names as text/template templates.
+type delimiters struct {
+	left, right string
+}
+
+// defaultDelimiters are the delimiters used when cookiecutter.json does
+// not declare "_delimiters".
+var defaultDelimiters = delimiters{left: "{{", right: "}}"}
+
+// extractDelimiters reads the "_delimiters" key from the answered
+// cookiecutter variables, falling back to defaultDelimiters if it's
+// absent or malformed.
+func extractDelimiters(data map[string]interface{}) delimiters {
+	raw, ok := data[delimitersKey]
+	if !ok {
+		return defaultDelimiters
+	}
+
+	pair, ok := raw.([]interface{})
+	if !ok || len(pair) != 2 {
+		return defaultDelimiters
+	}
+
+	left, leftOK := pair[0].(string)
+	right, rightOK := pair[1].(string)
+	if !leftOK || !rightOK || left == "" || right == "" {
+		return defaultDelimiters
+	}
+
+	return delimiters{left: left, right: right}
+}
+
+// isPrivateKey reports whether a cookiecutter.json key is template
+// metadata (like "_delimiters") rather than a prompted variable, following
+// Cookiecutter's convention that an underscore-prefixed key is private.
+func isPrivateKey(key string) bool {
+	return strings.HasPrefix(key, "_")
+}
+
+// findTemplateRoot locates the single top-level directory named with the
+// given delimiters (e.g. "{{cookiecutter.project_slug}}") inside
+// templateDir, which is Cookiecutter's convention for where the templated
+// tree lives, as opposed to repo-level metadata such as cookiecutter.json,
+// README, LICENSE, or hooks/. If no such directory exists, templateDir
+// itself is used, for backward compatibility with flat templates.
+func findTemplateRoot(templateDir string, delims delimiters) (string, error) {
+	entries, err := os.ReadDir(templateDir)
+	if err != nil {
+		return "", err
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if strings.Contains(entry.Name(), delims.left) && strings.Contains(entry.Name(), delims.right) {
+			return filepath.Join(templateDir, entry.Name()), nil
+		}
+	}
+
+	return templateDir, nil
+}